@@ -1,26 +1,122 @@
+// Package tui provides terminal-UI signal handling on top of os/signal.
+//
+// Since Go 1.14 the runtime uses SIGURG internally for goroutine
+// preemption, and on some platforms SIGPROF is likewise runtime-reserved
+// for profiling. Code that blindly forwards "every known signal" (as
+// CatchAll does) filters these two out, following the isRuntimeSig pattern
+// Moby introduced for the same reason: without it, a forwarder ends up
+// flooding its destination with runtime noise that has nothing to do with
+// the process's actual signal handling.
 package tui
 
 import (
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 )
 
 type Signal byte
 
 const (
-	SIGNULL Signal = 0
-	SIGINT  Signal = 1
-	SIGTERM Signal = 2
-	SIGTSTP Signal = 3
-	SIGHUP  Signal = 4
-	SIGTTIN Signal = 5
-	SIGTTOU Signal = 6
-	SIGUSR1 Signal = 7
-	SIGUSR2 Signal = 8
-	SIGALRM Signal = 9
+	SIGNULL  Signal = 0
+	SIGINT   Signal = 1
+	SIGTERM  Signal = 2
+	SIGTSTP  Signal = 3
+	SIGHUP   Signal = 4
+	SIGTTIN  Signal = 5
+	SIGTTOU  Signal = 6
+	SIGUSR1  Signal = 7
+	SIGUSR2  Signal = 8
+	SIGALRM  Signal = 9
+	SIGWINCH Signal = 10
+	SIGCONT  Signal = 11
+	SIGCHLD  Signal = 12
+	SIGPIPE  Signal = 13
+	SIGQUIT  Signal = 14
+	SIGABRT  Signal = 15
+	SIGBUS   Signal = 16
+	SIGFPE   Signal = 17
+	SIGSEGV  Signal = 18
+	SIGILL   Signal = 19
+	SIGURG   Signal = 20
+	SIGPROF  Signal = 21
 )
 
+// signalNames mirrors the SignalMap pattern used by Docker/podman's
+// pkg/signal: a single bidirectional table that both String() and
+// SignalByName() are built from.
+var signalNames = map[Signal]string{
+	SIGINT:   "INT",
+	SIGTERM:  "TERM",
+	SIGTSTP:  "TSTP",
+	SIGHUP:   "HUP",
+	SIGTTIN:  "TTIN",
+	SIGTTOU:  "TTOU",
+	SIGUSR1:  "USR1",
+	SIGUSR2:  "USR2",
+	SIGALRM:  "ALRM",
+	SIGWINCH: "WINCH",
+	SIGCONT:  "CONT",
+	SIGCHLD:  "CHLD",
+	SIGPIPE:  "PIPE",
+	SIGQUIT:  "QUIT",
+	SIGABRT:  "ABRT",
+	SIGBUS:   "BUS",
+	SIGFPE:   "FPE",
+	SIGSEGV:  "SEGV",
+	SIGILL:   "ILL",
+	SIGURG:   "URG",
+	SIGPROF:  "PROF",
+}
+
+var namesToSignal = func() map[string]Signal {
+	m := make(map[string]Signal, len(signalNames))
+	for sig, name := range signalNames {
+		m[name] = sig
+	}
+	return m
+}()
+
+// String returns the bare signal name, e.g. SIGWINCH.String() == "WINCH".
+func (s Signal) String() string {
+	if name, ok := signalNames[s]; ok {
+		return name
+	}
+	return "NULL"
+}
+
+// SignalByName looks up a Signal from its name. It accepts the bare form
+// ("WINCH"), the prefixed form ("SIGWINCH") case-insensitively, and decimal
+// numeric strings (e.g. "28") for scripting. It returns SIGNULL, false when
+// the name is not recognized.
+func SignalByName(name string) (Signal, bool) {
+	n := strings.ToUpper(strings.TrimSpace(name))
+	n = strings.TrimPrefix(n, "SIG")
+	if sig, ok := namesToSignal[n]; ok {
+		return sig, true
+	}
+	if num, err := strconv.Atoi(n); err == nil {
+		if sig := as_signal(syscall.Signal(num)); sig != SIGNULL {
+			return sig, true
+		}
+	}
+	return SIGNULL, false
+}
+
+// AllSignals returns every Signal known to this package, in ascending
+// numeric order, so callers can subscribe to the full set in one call.
+func AllSignals() []Signal {
+	all := make([]Signal, 0, len(signalNames))
+	for sig := range signalNames {
+		all = append(all, sig)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return all
+}
+
 func as_signal(which os.Signal) Signal {
 	switch which {
 	case os.Interrupt:
@@ -41,6 +137,30 @@ func as_signal(which os.Signal) Signal {
 		return SIGUSR2
 	case syscall.SIGALRM:
 		return SIGALRM
+	case syscall.SIGWINCH:
+		return SIGWINCH
+	case syscall.SIGCONT:
+		return SIGCONT
+	case syscall.SIGCHLD:
+		return SIGCHLD
+	case syscall.SIGPIPE:
+		return SIGPIPE
+	case syscall.SIGQUIT:
+		return SIGQUIT
+	case syscall.SIGABRT:
+		return SIGABRT
+	case syscall.SIGBUS:
+		return SIGBUS
+	case syscall.SIGFPE:
+		return SIGFPE
+	case syscall.SIGSEGV:
+		return SIGSEGV
+	case syscall.SIGILL:
+		return SIGILL
+	case syscall.SIGURG:
+		return SIGURG
+	case syscall.SIGPROF:
+		return SIGPROF
 	default:
 		return SIGNULL
 	}
@@ -68,21 +188,105 @@ func as_go_signal(which Signal) os.Signal {
 		return syscall.SIGUSR2
 	case SIGALRM:
 		return syscall.SIGALRM
+	case SIGWINCH:
+		return syscall.SIGWINCH
+	case SIGCONT:
+		return syscall.SIGCONT
+	case SIGCHLD:
+		return syscall.SIGCHLD
+	case SIGPIPE:
+		return syscall.SIGPIPE
+	case SIGQUIT:
+		return syscall.SIGQUIT
+	case SIGABRT:
+		return syscall.SIGABRT
+	case SIGBUS:
+		return syscall.SIGBUS
+	case SIGFPE:
+		return syscall.SIGFPE
+	case SIGSEGV:
+		return syscall.SIGSEGV
+	case SIGILL:
+		return syscall.SIGILL
+	case SIGURG:
+		return syscall.SIGURG
+	case SIGPROF:
+		return syscall.SIGPROF
 	default:
 		return zero_go_signal
 	}
 }
 
+// isRuntimeSig reports whether sig is reserved by the Go runtime itself
+// (SIGURG for goroutine preemption since Go 1.14, and SIGPROF for
+// profiling) and should therefore never be forwarded by a "catch all"
+// subscriber.
+func isRuntimeSig(sig Signal) bool {
+	switch sig {
+	case SIGURG, SIGPROF:
+		return true
+	default:
+		return false
+	}
+}
+
 func write_signal(dest *os.File, which os.Signal) error {
-	b := make([]byte, 1)
-	b[0] = byte(as_signal(which))
-	if b[0] == 0 {
+	sig := as_signal(which)
+	if sig == SIGNULL || isRuntimeSig(sig) {
 		return nil
 	}
+	b := []byte{byte(sig)}
 	_, err := dest.Write(b)
 	return err
 }
 
+// CatchAll subscribes c to every signal known to this package except the
+// ones the Go runtime reserves for itself (see isRuntimeSig). It returns a
+// stop func that reverts exactly the signals it installed.
+func CatchAll(c chan Signal) func() {
+	all := AllSignals()
+	wanted := make([]Signal, 0, len(all))
+	for _, sig := range all {
+		if !isRuntimeSig(sig) {
+			wanted = append(wanted, sig)
+		}
+	}
+	raw := make(chan os.Signal, cap_or_min(len(wanted)))
+	remove := notify_signals(raw, wanted...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case which, ok := <-raw:
+				if !ok {
+					return
+				}
+				sig := as_signal(which)
+				if isRuntimeSig(sig) {
+					continue
+				}
+				select {
+				case c <- sig:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		remove()
+	}
+}
+
+func cap_or_min(n int) int {
+	if n < 16 {
+		return 16
+	}
+	return n
+}
+
 func notify_signals(c chan os.Signal, signals ...Signal) func() {
 	s := make([]os.Signal, len(signals))
 	for i, x := range signals {
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// NotifyContext mirrors signal.NotifyContext from the standard library: it
+// returns a copy of parent with a new Done channel that is closed as soon as
+// one of the listed signals arrives, or when the returned stop function is
+// called, whichever happens first.
+func NotifyContext(parent context.Context, signals ...Signal) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	c := make(chan os.Signal, 1)
+	remove := notify_signals(c, signals...)
+	go func() {
+		select {
+		case <-c:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	stop := func() {
+		remove()
+		cancel()
+	}
+	return ctx, stop
+}
+
+// SignalDispatcher fans incoming signals out to per-signal handlers. Unlike
+// a raw notify_signals channel, it lets callers register as many handlers
+// as they like and guarantees handlers for a given signal run serially.
+type SignalDispatcher struct {
+	mu       sync.Mutex
+	handlers map[Signal][]func()
+	c        chan os.Signal
+	remove   func()
+	stopped  bool
+}
+
+// NewSignalDispatcher creates a dispatcher with no handlers and no signals
+// installed yet. Use On to register handlers; the underlying os/signal
+// subscription is (re)installed automatically to cover every signal that
+// has at least one handler. The dispatcher uses a single long-lived
+// channel and goroutine for its whole life, so Add/Remove at runtime never
+// leaks a goroutine.
+func NewSignalDispatcher() *SignalDispatcher {
+	d := &SignalDispatcher{handlers: make(map[Signal][]func()), c: make(chan os.Signal, 16)}
+	go d.run(d.c)
+	return d
+}
+
+// On registers fn to run whenever sig is received. It may be called
+// multiple times for the same signal; handlers run in registration order.
+func (d *SignalDispatcher) On(sig Signal, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[sig] = append(d.handlers[sig], fn)
+	d.reinstall_locked()
+}
+
+// Remove drops every handler registered for sig.
+func (d *SignalDispatcher) Remove(sig Signal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.handlers, sig)
+	d.reinstall_locked()
+}
+
+// reinstall_locked only ever changes the signal.Notify registration on the
+// dispatcher's single long-lived channel; it never replaces d.c or spawns
+// another run goroutine, so repeated On/Remove calls cannot leak either.
+func (d *SignalDispatcher) reinstall_locked() {
+	if d.stopped {
+		return
+	}
+	if d.remove != nil {
+		d.remove()
+		d.remove = nil
+	}
+	if len(d.handlers) == 0 {
+		return
+	}
+	signals := make([]Signal, 0, len(d.handlers))
+	for sig := range d.handlers {
+		signals = append(signals, sig)
+	}
+	d.remove = notify_signals(d.c, signals...)
+}
+
+func (d *SignalDispatcher) run(c chan os.Signal) {
+	for which := range c {
+		sig := as_signal(which)
+		d.mu.Lock()
+		handlers := append([]func(){}, d.handlers[sig]...)
+		d.mu.Unlock()
+		for _, fn := range handlers {
+			fn()
+		}
+	}
+}
+
+// Stop calls signal.Reset on exactly the subset of signals this dispatcher
+// actually installed, not the global set, so it composes cleanly with other
+// packages that also use os/signal.
+func (d *SignalDispatcher) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	d.stopped = true
+	if d.remove != nil {
+		d.remove()
+		d.remove = nil
+	}
+	if d.c != nil {
+		close(d.c)
+		d.c = nil
+	}
+}
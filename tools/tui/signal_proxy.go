@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// ProxySignals forwards the given signals to proc as they arrive, the way
+// podman's sigproxy forwards signals to the container process it wraps.
+// This is what lets a TUI that shells out to $EDITOR or a pager behave
+// correctly when the terminal is resized or the user hits Ctrl-Z/Ctrl-C.
+//
+// SIGCHLD is never proxied (it describes proc's own children, not
+// something proc should receive again). SIGKILL and SIGSTOP have no Signal
+// value in this package and cannot be caught, so they never reach the
+// proxy. A resize storm is
+// coalesced so SIGWINCH cannot back up the child's signal queue. Forwarding
+// stops when ctx is done or the returned stop func is called; delivery
+// errors that mean proc has already exited (e.g. from proc.Wait having
+// already returned) are ignored rather than surfaced.
+func ProxySignals(ctx context.Context, proc *os.Process, signals ...Signal) func() {
+	wanted := make([]Signal, 0, len(signals))
+	for _, sig := range signals {
+		switch sig {
+		case SIGCHLD:
+			continue
+		default:
+			wanted = append(wanted, sig)
+		}
+	}
+
+	raw := make(chan os.Signal, 16)
+	remove := notify_signals(raw, wanted...)
+	done := make(chan struct{})
+
+	go func() {
+		defer remove()
+		var pending *Signal
+		for {
+			var sig Signal
+			if pending != nil {
+				sig, pending = *pending, nil
+			} else {
+				select {
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				case which, ok := <-raw:
+					if !ok {
+						return
+					}
+					sig = as_signal(which)
+				}
+			}
+			if sig == SIGWINCH {
+				// Collapse a resize storm into a single forwarded
+				// SIGWINCH: drain whatever is already queued instead of
+				// forwarding one per terminal event. A non-WINCH signal
+				// found mid-drain is stashed in pending rather than
+				// discarded, so it still gets its own forwarding pass.
+			drain:
+				for {
+					select {
+					case next := <-raw:
+						if nextSig := as_signal(next); nextSig != SIGWINCH {
+							pending = &nextSig
+							break drain
+						}
+					default:
+						break drain
+					}
+				}
+			}
+			if err := proc.Signal(as_go_signal(sig)); err != nil && !process_finished(err) {
+				continue
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// process_finished reports whether err from (*os.Process).Signal indicates
+// the process has already exited, which proc.Wait returning concurrently
+// can legitimately cause while a proxy goroutine is still forwarding.
+func process_finished(err error) bool {
+	if err == nil {
+		return false
+	}
+	s := err.Error()
+	return strings.Contains(s, "process already finished") || strings.Contains(s, "no such process")
+}
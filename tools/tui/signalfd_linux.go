@@ -0,0 +1,184 @@
+//go:build linux
+
+package tui
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// SignalInfo carries the metadata signalfd(2) attaches to each delivered
+// signal, for callers of NotifySignalfd that want more than just the
+// Signal value.
+type SignalInfo struct {
+	Signal Signal
+	Pid    uint32 // ssi_pid: pid of the process that sent the signal, if any
+	Uid    uint32 // ssi_uid: uid of the sender, if any
+}
+
+// sigset_add sets the bit for signal number n (1-based, as in signal(7))
+// in set. x/sys/unix does not expose a sigaddset helper, so this hand-sets
+// the bit in the underlying Val[16]uint64 array the same way sigaddset(3)
+// would.
+func sigset_add(set *unix.Sigset_t, n int) {
+	set.Val[(n-1)/64] |= 1 << (uint(n-1) % 64)
+}
+
+// NotifySignalfd delivers signals synchronously via Linux's signalfd(2),
+// instead of the buffered, coalescing channel used by signal.Notify.
+//
+// Caveat: pthread_sigmask only blocks signals on the calling OS thread, not
+// process-wide. This blocks the requested signals on one dedicated OS
+// thread that it keeps locked for the whole lifetime of the subscription,
+// then creates the signalfd from that same blocked mask. Other OS threads
+// that already exist in the process (GC workers, sysmon, threads parked in
+// a blocking syscall) do not have these signals blocked, so the kernel can
+// still choose one of them for delivery instead of routing the signal into
+// the fd — in which case that delivery never reaches c: NotifySignalfd
+// installs no signal.Notify, so the signal gets whatever handling os/signal
+// and the Go runtime otherwise give it. Call this as early as possible (e.g.
+// from init or the top of main), before the runtime has spun up many OS
+// threads, to minimize that window.
+func NotifySignalfd(c chan Signal, signals ...Signal) (stop func(), err error) {
+	stop, _, err = notify_rich_signalfd(nil, c, signals...)
+	return stop, err
+}
+
+// NotifyRichSignalfd is like NotifySignalfd but additionally delivers the
+// ssi_pid/ssi_uid metadata for every signal to info, for callers that need
+// to know who sent it.
+func NotifyRichSignalfd(info chan SignalInfo, c chan Signal, signals ...Signal) (stop func(), err error) {
+	stop, _, err = notify_rich_signalfd(info, c, signals...)
+	return stop, err
+}
+
+// notify_rich_signalfd is the shared implementation behind NotifySignalfd
+// and NotifyRichSignalfd. It additionally returns the tid of the dedicated
+// OS thread that blocks the signal mask and owns the fd, which the two
+// exported wrappers discard: tests in this package use it to target that
+// exact thread with tgkill for deterministic delivery, since a
+// process-directed signal can otherwise land on any other, unblocked OS
+// thread in the process (see the caveat on NotifySignalfd).
+func notify_rich_signalfd(info chan SignalInfo, c chan Signal, signals ...Signal) (stop func(), tid int, err error) {
+	var mask unix.Sigset_t
+	for _, sig := range signals {
+		g := as_go_signal(sig)
+		if g == zero_go_signal {
+			continue
+		}
+		sigset_add(&mask, int(g.(syscall.Signal)))
+	}
+
+	// The OS thread that blocks the mask is the only one Stop can
+	// correctly unblock it on, so it stays locked and parked here for the
+	// life of the subscription rather than being released after setup.
+	type setup struct {
+		fd  int
+		tid int
+		err error
+	}
+	ready := make(chan setup, 1)
+	unblock := make(chan struct{})
+	threadDone := make(chan struct{})
+	go func() {
+		defer close(threadDone)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		if serr := unix.PthreadSigmask(unix.SIG_BLOCK, &mask, nil); serr != nil {
+			ready <- setup{err: serr}
+			return
+		}
+		fd, ferr := unix.Signalfd(-1, &mask, unix.SFD_CLOEXEC)
+		if ferr != nil {
+			unix.PthreadSigmask(unix.SIG_UNBLOCK, &mask, nil)
+			ready <- setup{err: ferr}
+			return
+		}
+		ready <- setup{fd: fd, tid: unix.Gettid()}
+		<-unblock
+		unix.PthreadSigmask(unix.SIG_UNBLOCK, &mask, nil)
+	}()
+
+	res := <-ready
+	if res.err != nil {
+		return nil, 0, res.err
+	}
+	fd := res.fd
+
+	// unix.Close(fd) alone cannot safely cancel the blocking unix.Read
+	// below: on Linux, closing an fd from another goroutine does not
+	// reliably unblock a concurrent read() on it, so the stale read can
+	// stay parked on the old, not-yet-destroyed signalfd object and win
+	// the delivery race against a later subscription for the same signal,
+	// silently stealing it. stopfd is an eventfd used purely to wake the
+	// poll below on demand, so the read loop always exits promptly and
+	// deterministically instead of leaking a zombie reader.
+	stopfd, serr := unix.Eventfd(0, unix.EFD_CLOEXEC)
+	if serr != nil {
+		unix.Close(fd)
+		close(unblock)
+		<-threadDone
+		return nil, 0, serr
+	}
+
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		const sizeofSignalfdSiginfo = int(unsafe.Sizeof(unix.SignalfdSiginfo{}))
+		buf := make([]byte, sizeofSignalfdSiginfo)
+		pollfds := []unix.PollFd{
+			{Fd: int32(fd), Events: unix.POLLIN},
+			{Fd: int32(stopfd), Events: unix.POLLIN},
+		}
+		for {
+			_, perr := unix.Poll(pollfds, -1)
+			if perr != nil {
+				if perr == unix.EINTR {
+					continue
+				}
+				return
+			}
+			if pollfds[1].Revents&unix.POLLIN != 0 {
+				return
+			}
+			if pollfds[0].Revents&unix.POLLIN == 0 {
+				continue
+			}
+			n, rerr := unix.Read(fd, buf)
+			if rerr != nil || n != sizeofSignalfdSiginfo {
+				if rerr == unix.EINTR {
+					continue
+				}
+				return
+			}
+			raw := (*unix.SignalfdSiginfo)(unsafe.Pointer(&buf[0]))
+			sig := as_signal(syscall.Signal(raw.Signo))
+			if sig == SIGNULL {
+				continue
+			}
+			if info != nil {
+				select {
+				case info <- SignalInfo{Signal: sig, Pid: raw.Pid, Uid: raw.Uid}:
+				default:
+				}
+			}
+			select {
+			case c <- sig:
+			default:
+			}
+		}
+	}()
+
+	stop = func() {
+		unix.Write(stopfd, []byte{1, 0, 0, 0, 0, 0, 0, 0})
+		<-readerDone
+		unix.Close(fd)
+		unix.Close(stopfd)
+		close(unblock)
+		<-threadDone
+	}
+	return stop, res.tid, nil
+}
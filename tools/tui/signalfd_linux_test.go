@@ -0,0 +1,67 @@
+//go:build linux
+
+package tui
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// A process-directed kill(2) can land on any OS thread in the test binary
+// that doesn't have the signal blocked, not just the one this package
+// dedicates to the signalfd (see the caveat on NotifySignalfd). These
+// tests use tgkill against that exact thread, via the unexported
+// notify_rich_signalfd, so delivery through the fd is deterministic rather
+// than racing the rest of the runtime's threads.
+
+func TestNotifySignalfdDeliversRealSignal(t *testing.T) {
+	c := make(chan Signal, 1)
+	stop, tid, err := notify_rich_signalfd(nil, c, SIGUSR1)
+	if err != nil {
+		t.Fatalf("notify_rich_signalfd: %v", err)
+	}
+	defer stop()
+
+	if err := unix.Tgkill(os.Getpid(), tid, unix.SIGUSR1); err != nil {
+		t.Fatalf("tgkill: %v", err)
+	}
+
+	select {
+	case sig := <-c:
+		if sig != SIGUSR1 {
+			t.Fatalf("got signal %v, want SIGUSR1", sig)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for SIGUSR1 via signalfd")
+	}
+}
+
+func TestNotifyRichSignalfdReportsSender(t *testing.T) {
+	c := make(chan Signal, 1)
+	info := make(chan SignalInfo, 1)
+	stop, tid, err := notify_rich_signalfd(info, c, SIGUSR2)
+	if err != nil {
+		t.Fatalf("notify_rich_signalfd: %v", err)
+	}
+	defer stop()
+
+	pid := os.Getpid()
+	if err := unix.Tgkill(pid, tid, unix.SIGUSR2); err != nil {
+		t.Fatalf("tgkill: %v", err)
+	}
+
+	select {
+	case got := <-info:
+		if got.Signal != SIGUSR2 {
+			t.Fatalf("got signal %v, want SIGUSR2", got.Signal)
+		}
+		if int(got.Pid) != pid {
+			t.Fatalf("got ssi_pid %d, want %d", got.Pid, pid)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for SIGUSR2 via signalfd")
+	}
+}
@@ -0,0 +1,53 @@
+//go:build !linux
+
+package tui
+
+import (
+	"os"
+)
+
+// SignalInfo carries the metadata signalfd(2) attaches to each delivered
+// signal on Linux. Outside Linux it is unused; NotifySignalfd falls back
+// to the plain signal.Notify path and never populates it.
+type SignalInfo struct {
+	Signal Signal
+	Pid    uint32
+	Uid    uint32
+}
+
+// NotifySignalfd falls back to the ordinary signal.Notify path on
+// platforms without signalfd(2). Delivery is therefore subject to the same
+// buffering and coalescing as notify_signals.
+func NotifySignalfd(c chan Signal, signals ...Signal) (stop func(), err error) {
+	raw := make(chan os.Signal, 16)
+	remove := notify_signals(raw, signals...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case which, ok := <-raw:
+				if !ok {
+					return
+				}
+				select {
+				case c <- as_signal(which):
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	stop = func() {
+		close(done)
+		remove()
+	}
+	return stop, nil
+}
+
+// NotifyRichSignalfd falls back the same way as NotifySignalfd; info never
+// receives values on non-Linux platforms since there is no sender metadata
+// to report.
+func NotifyRichSignalfd(info chan SignalInfo, c chan Signal, signals ...Signal) (stop func(), err error) {
+	return NotifySignalfd(c, signals...)
+}